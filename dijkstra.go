@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"sync"
 )
 
 // Point is a representation of a point on a map
@@ -106,24 +107,138 @@ func (d *DijkstraMap) Calc(points ...Point) {
 		mademutation = false
 		for x := range d.Points {
 			for y := range d.Points[x] {
-				if d.M.IsPassable(x, y) {
-					ln := d.LowestNeighbour(x, y).Val
-					if d.Points[x][y] > ln+1 {
-						d.Points[x][y] = ln + 1
-						mademutation = true
-					}
+				if d.relax(x, y) {
+					mademutation = true
 				}
 				x1, y1 := (d.M.SizeX()-1)-x, (d.M.SizeY()-1)-y
-				if d.M.IsPassable(x1, y1) {
-					ln := d.LowestNeighbour(x1, y1).Val
-					if d.Points[x1][y1] > ln+1 {
-						d.Points[x1][y1] = ln + 1
-						mademutation = true
+				if d.relax(x1, y1) {
+					mademutation = true
+				}
+			}
+		}
+	}
+}
+
+// ParallelTileSize is the width and height of the tiles that
+// CalcParallel divides the map into. A NeigbourFunc used with
+// CalcParallel must not return points more than this many cells away
+// from the point it was given.
+const ParallelTileSize = 16
+
+// CalcParallel is like Calc, but spreads the work over the given
+// number of goroutines. The result is identical to that of Calc on
+// every platform, no matter how many workers are used or how they're
+// scheduled, which makes it suitable for lockstep multiplayer: like
+// Calc, it keeps relaxing until nothing changes, and that fixed point
+// is the same whatever order the points are relaxed in. The map is cut
+// into tiles which are coloured like a checkerboard with four colours,
+// and one colour is relaxed at a time; this only stops two tiles being
+// worked on at once from touching each other's ranks. Every method of
+// your Map and the NeigbourFunc must be safe to call from multiple
+// goroutines.
+func (d *DijkstraMap) CalcParallel(workers int, points ...Point) {
+	if workers < 1 {
+		workers = 1
+	}
+	for _, point := range points {
+		x, y := point.GetXY()
+		d.Points[x][y] = 0
+	}
+	sx, sy := d.M.SizeX(), d.M.SizeY()
+	var phases [4][][2]int
+	for x := 0; x < sx; x += ParallelTileSize {
+		for y := 0; y < sy; y += ParallelTileSize {
+			colour := (x/ParallelTileSize)%2 + 2*((y/ParallelTileSize)%2)
+			phases[colour] = append(phases[colour], [2]int{x, y})
+		}
+	}
+	changed := make([]bool, len(phases[0]))
+	mademutation := true
+	for mademutation {
+		mademutation = false
+		for _, tiles := range phases {
+			jobs := make(chan int)
+			wg := sync.WaitGroup{}
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range jobs {
+						changed[i] = d.relaxTile(tiles[i][0], tiles[i][1], sx, sy)
 					}
+				}()
+			}
+			for i := range tiles {
+				jobs <- i
+			}
+			close(jobs)
+			wg.Wait()
+			for i := range tiles {
+				if changed[i] {
+					mademutation = true
+				}
+			}
+		}
+	}
+}
+
+// relaxTile relaxes the tile with its top-left corner at x0, y0 on a
+// map of size sx, sy until it stops changing, and returns true if any
+// rank in it was lowered.
+func (d *DijkstraMap) relaxTile(x0, y0, sx, sy int) bool {
+	x1, y1 := x0+ParallelTileSize, y0+ParallelTileSize
+	if x1 > sx {
+		x1 = sx
+	}
+	if y1 > sy {
+		y1 = sy
+	}
+	ret := false
+	mademutation := true
+	for mademutation {
+		mademutation = false
+		for x := x0; x < x1; x++ {
+			for y := y0; y < y1; y++ {
+				if d.relax(x, y) {
+					mademutation = true
+				}
+				if d.relax(x1-1-(x-x0), y1-1-(y-y0)) {
+					mademutation = true
 				}
 			}
 		}
+		if mademutation {
+			ret = true
+		}
+	}
+	return ret
+}
+
+// RelaxCell lowers the rank of the point at x, y to one more than that
+// of its lowest neighbour, returning true if the rank changed. This is
+// the single step Calc repeats over the whole map until nothing
+// changes; you can use it to build your own update schedules, such as
+// only relaxing the corridor around a moving target. Impassable and
+// out of bounds points are never changed.
+func (d *DijkstraMap) RelaxCell(x, y int) bool {
+	if d.M.OOB(x, y) {
+		return false
+	}
+	return d.relax(x, y)
+}
+
+// relax is RelaxCell without the bounds check, for use by the sweeps in
+// Calc and CalcParallel which only visit points on the map.
+func (d *DijkstraMap) relax(x, y int) bool {
+	if !d.M.IsPassable(x, y) {
+		return false
+	}
+	ln := d.LowestNeighbour(x, y).Val
+	if d.Points[x][y] > ln+1 {
+		d.Points[x][y] = ln + 1
+		return true
 	}
+	return false
 }
 
 // Recalc recalculates the Dijkstra map with points given as
@@ -0,0 +1,89 @@
+package dmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type testMap struct {
+	walls [][]bool
+}
+
+func (m *testMap) SizeX() int {
+	return len(m.walls)
+}
+
+func (m *testMap) SizeY() int {
+	return len(m.walls[0])
+}
+
+func (m *testMap) IsPassable(x, y int) bool {
+	return !m.walls[x][y]
+}
+
+func (m *testMap) OOB(x, y int) bool {
+	return x < 0 || y < 0 || x >= m.SizeX() || y >= m.SizeY()
+}
+
+func randomMap(r *rand.Rand) *testMap {
+	walls := make([][]bool, r.Intn(80)+1)
+	sy := r.Intn(80) + 1
+	for x := range walls {
+		walls[x] = make([]bool, sy)
+		for y := range walls[x] {
+			walls[x][y] = r.Intn(4) == 0
+		}
+	}
+	return &testMap{walls}
+}
+
+func TestCalcParallelMatchesCalc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 30; i++ {
+		m := randomMap(r)
+		neighbours := ManhattanNeighbours
+		if i%2 == 1 {
+			neighbours = DiagonalNeighbours
+		}
+		targets := []Point{
+			&WeightedPoint{X: r.Intn(m.SizeX()), Y: r.Intn(m.SizeY())},
+			&WeightedPoint{X: r.Intn(m.SizeX()), Y: r.Intn(m.SizeY())},
+		}
+		want := BlankDMap(m, neighbours)
+		want.Calc(targets...)
+		for workers := 1; workers <= 8; workers++ {
+			got := BlankDMap(m, neighbours)
+			got.CalcParallel(workers, targets...)
+			if got.String() != want.String() {
+				t.Fatalf("map %d, %d workers: CalcParallel differs from Calc\ngot:\n%s\nwant:\n%s",
+					i, workers, got, want)
+			}
+		}
+	}
+}
+
+func TestRelaxCell(t *testing.T) {
+	m := &testMap{[][]bool{
+		{false, false, false},
+		{false, true, false},
+	}}
+	d := BlankDMap(m, ManhattanNeighbours)
+	d.Points[0][0] = 0
+	if !d.RelaxCell(0, 1) || d.Points[0][1] != 1 {
+		t.Fatalf("RelaxCell(0, 1) didn't lower the rank to 1, got %d", d.Points[0][1])
+	}
+	if d.RelaxCell(0, 1) {
+		t.Fatal("RelaxCell(0, 1) reported a change when the rank was already lowest")
+	}
+	if d.RelaxCell(1, 1) || d.Points[1][1] != RankMax {
+		t.Fatal("RelaxCell changed an impassable point")
+	}
+	for _, p := range [][2]int{{-1, 0}, {0, -1}, {2, 0}, {0, 3}} {
+		if d.RelaxCell(p[0], p[1]) {
+			t.Fatalf("RelaxCell(%d, %d) reported a change out of bounds", p[0], p[1])
+		}
+	}
+	if d.Points[1][0] != RankMax || d.Points[1][2] != RankMax || d.Points[0][2] != RankMax {
+		t.Fatalf("RelaxCell changed points it wasn't asked to:\n%s", d)
+	}
+}
@@ -0,0 +1,173 @@
+// Package presets provides ready-made roguelike AI behaviours built
+// out of dmaps. Each preset is a function returning a new Behaviour,
+// which bundles a neighbour function with a weighted blend of Dijkstra
+// maps; change its fields to make your own. There are no cost layers, since dmap
+// only supports a uniform cost of one per step; to make a creature
+// avoid some terrain, have your Map report it as impassable.
+package presets
+
+import (
+	"math"
+
+	"github.com/japanoise/dmap"
+)
+
+// Goal says which set of points a dmap in a Behaviour is calculated from
+type Goal int
+
+const (
+	// Enemies are the things a creature hunts or is afraid of
+	Enemies Goal = iota
+	// Items are the things a creature wants to pick up
+	Items
+)
+
+// Component is one of the Dijkstra maps blended together by a
+// Behaviour. Its ranks are multiplied by Weight before being summed
+// with the other components. If Flee is non-zero, the map is turned
+// into a Brogue-style safety map by multiplying it by -Flee and
+// recalculating, so the creature runs away intelligently rather than
+// into corners; 1.2 is a good value.
+type Component struct {
+	Goal   Goal
+	Weight float64
+	Flee   float64
+}
+
+// Behaviour is a recipe for an AI. Neighbours is the neighbour function
+// used for its dmaps and movement, and Components are blended together
+// to give the score of each tile; the creature moves to minimise it.
+// A tile only beats the best one so far if its score is more than
+// Epsilon lower, so scores that differ only by floating point rounding
+// don't flip the choice between runs on different architectures.
+type Behaviour struct {
+	Neighbours func(d *dmap.DijkstraMap, x, y int) []dmap.WeightedPoint
+	Components []Component
+	Epsilon    float64
+}
+
+// DefaultEpsilon is the Epsilon used by the presets. It's far larger
+// than any rounding error in a score, but far smaller than the
+// difference a single rank makes with any sensible weight.
+const DefaultEpsilon = 1e-6
+
+// MeleeChaser heads straight for the nearest enemy
+func MeleeChaser() Behaviour {
+	return Behaviour{
+		Neighbours: dmap.DiagonalNeighbours,
+		Components: []Component{
+			{Goal: Enemies, Weight: 1},
+		},
+		Epsilon: DefaultEpsilon,
+	}
+}
+
+// CowardlyRanged keeps away from enemies, backing off around corners
+// rather than into dead ends
+func CowardlyRanged() Behaviour {
+	return Behaviour{
+		Neighbours: dmap.DiagonalNeighbours,
+		Components: []Component{
+			{Goal: Enemies, Weight: 1, Flee: 1.2},
+		},
+		Epsilon: DefaultEpsilon,
+	}
+}
+
+// Scavenger goes after items, but gives enemies a wide berth while
+// doing so
+func Scavenger() Behaviour {
+	return Behaviour{
+		Neighbours: dmap.DiagonalNeighbours,
+		Components: []Component{
+			{Goal: Items, Weight: 1},
+			{Goal: Enemies, Weight: 0.5, Flee: 1.2},
+		},
+		Epsilon: DefaultEpsilon,
+	}
+}
+
+// Mind is a Behaviour that has been set up on a particular map
+type Mind struct {
+	Behaviour Behaviour
+	Maps      []*dmap.DijkstraMap
+}
+
+// NewMind creates a Mind for the Behaviour on the map passed to it. The
+// Behaviour is copied, so later changes to it won't affect the Mind.
+func NewMind(b Behaviour, m dmap.Map) *Mind {
+	b.Components = append([]Component(nil), b.Components...)
+	maps := make([]*dmap.DijkstraMap, len(b.Components))
+	for i := range maps {
+		maps[i] = dmap.BlankDMap(m, b.Neighbours)
+	}
+	return &Mind{b, maps}
+}
+
+// Update recalculates the Mind's dmaps using the points given for each
+// Goal. Call it whenever the things it cares about move.
+func (mi *Mind) Update(goals map[Goal][]dmap.Point) {
+	for i, c := range mi.Behaviour.Components {
+		mi.Maps[i].Recalc(goals[c.Goal]...)
+		if c.Flee != 0 {
+			flee(mi.Maps[i], c.Flee)
+		}
+	}
+}
+
+// Score returns the blended score of the tile at x, y; lower is
+// better.
+func (mi *Mind) Score(x, y int) float64 {
+	var ret float64
+	for i, c := range mi.Behaviour.Components {
+		// The explicit conversion rounds the product before it's added,
+		// so it can't be fused into an FMA on some architectures and
+		// give a different score.
+		ret += float64(c.Weight * float64(mi.Maps[i].GetValPoint(x, y).Val))
+	}
+	return ret
+}
+
+// Move returns the tile the creature at x, y should move to. It's
+// either one of its passable neighbours or x, y itself if standing
+// still is best. Ties, within the Behaviour's Epsilon, go to staying
+// still and then to the earliest neighbour.
+func (mi *Mind) Move(x, y int) (int, int) {
+	if len(mi.Maps) == 0 {
+		return x, y
+	}
+	m := mi.Maps[0].M
+	bx, by, best := x, y, mi.Score(x, y)
+	for _, n := range mi.Behaviour.Neighbours(mi.Maps[0], x, y) {
+		if m.OOB(n.X, n.Y) || !m.IsPassable(n.X, n.Y) {
+			continue
+		}
+		if s := mi.Score(n.X, n.Y); s < best-mi.Behaviour.Epsilon {
+			bx, by, best = n.X, n.Y, s
+		}
+	}
+	return bx, by
+}
+
+// flee turns d into a safety map by multiplying the reachable ranks by
+// -coef, shifting them so they stay positive, and recalculating.
+func flee(d *dmap.DijkstraMap, coef float64) {
+	var highest dmap.Rank
+	for x := range d.Points {
+		for y := range d.Points[x] {
+			if d.Points[x][y] < dmap.RankMax && d.Points[x][y] > highest {
+				highest = d.Points[x][y]
+			}
+		}
+	}
+	base := math.Ceil(coef * float64(highest))
+	for x := range d.Points {
+		for y := range d.Points[x] {
+			if d.Points[x][y] < dmap.RankMax {
+				v := base - math.Round(coef*float64(d.Points[x][y]))
+				d.Points[x][y] = dmap.Rank(math.Max(0, math.Min(v, dmap.RankMax-1)))
+			}
+		}
+	}
+	d.Calc()
+}
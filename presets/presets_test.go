@@ -0,0 +1,149 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/japanoise/dmap"
+)
+
+type testMap struct {
+	walls [][]bool
+}
+
+func (m *testMap) SizeX() int {
+	return len(m.walls)
+}
+
+func (m *testMap) SizeY() int {
+	return len(m.walls[0])
+}
+
+func (m *testMap) IsPassable(x, y int) bool {
+	return !m.walls[x][y]
+}
+
+func (m *testMap) OOB(x, y int) bool {
+	return x < 0 || y < 0 || x >= m.SizeX() || y >= m.SizeY()
+}
+
+func openMap(sx, sy int) *testMap {
+	walls := make([][]bool, sx)
+	for x := range walls {
+		walls[x] = make([]bool, sy)
+	}
+	return &testMap{walls}
+}
+
+// distance is the number of diagonal moves between two points
+func distance(x0, y0, x1, y1 int) int {
+	dx, dy := x1-x0, y1-y0
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+func TestMeleeChaser(t *testing.T) {
+	m := openMap(10, 10)
+	m.walls[3][3] = true
+	mi := NewMind(MeleeChaser(), m)
+	mi.Update(map[Goal][]dmap.Point{Enemies: {&dmap.WeightedPoint{X: 5, Y: 5}}})
+	if x, y := mi.Move(8, 8); x != 7 || y != 7 {
+		t.Fatalf("moved from 8, 8 to %d, %d, want 7, 7", x, y)
+	}
+	// The direct route is walled off, so it should step around the
+	// wall, picking the first of the equally good neighbours.
+	if x, y := mi.Move(2, 2); x != 3 || y != 2 {
+		t.Fatalf("moved from 2, 2 to %d, %d, want 3, 2", x, y)
+	}
+}
+
+func TestCowardlyRanged(t *testing.T) {
+	m := openMap(10, 10)
+	mi := NewMind(CowardlyRanged(), m)
+	mi.Update(map[Goal][]dmap.Point{Enemies: {&dmap.WeightedPoint{X: 5, Y: 5}}})
+	x, y := mi.Move(3, 3)
+	if distance(x, y, 5, 5) <= distance(3, 3, 5, 5) {
+		t.Fatalf("moved from 3, 3 to %d, %d, which isn't further from the enemy", x, y)
+	}
+}
+
+func TestScavenger(t *testing.T) {
+	m := openMap(10, 10)
+	mi := NewMind(Scavenger(), m)
+	mi.Update(map[Goal][]dmap.Point{Items: {&dmap.WeightedPoint{X: 9, Y: 0}}})
+	x, y := mi.Move(5, 2)
+	if distance(x, y, 9, 0) >= distance(5, 2, 9, 0) {
+		t.Fatalf("moved from 5, 2 to %d, %d, which isn't closer to the item", x, y)
+	}
+}
+
+func TestPresetsAreFresh(t *testing.T) {
+	b := Scavenger()
+	b.Components[1].Weight = 2
+	if Scavenger().Components[1].Weight != 0.5 {
+		t.Fatal("changing a preset's components changed the preset")
+	}
+}
+
+func TestFlee(t *testing.T) {
+	// A corridor with a wall at 20, so 21 onwards can't be reached
+	m := openMap(30, 1)
+	m.walls[20][0] = true
+	for _, coef := range []float64{1.2, 100, 5000} {
+		d := dmap.BlankDMap(m, dmap.ManhattanNeighbours)
+		d.Calc(&dmap.WeightedPoint{X: 0, Y: 0})
+		flee(d, coef)
+		for x := 20; x < 30; x++ {
+			if d.Points[x][0] != dmap.RankMax {
+				t.Fatalf("coef %v: rank at %d is %d, want RankMax", coef, x, d.Points[x][0])
+			}
+		}
+		// Ranks should fall steadily away from the enemy; if the
+		// arithmetic had wrapped around below 0 they'd jump back up.
+		for x := 1; x < 20; x++ {
+			if d.Points[x][0] > d.Points[x-1][0] {
+				t.Fatalf("coef %v: rank rises from %d at %d to %d at %d",
+					coef, d.Points[x-1][0], x-1, d.Points[x][0], x)
+			}
+		}
+		if d.Points[19][0] != 0 {
+			t.Fatalf("coef %v: safest rank is %d, want 0", coef, d.Points[19][0])
+		}
+		if d.Points[0][0] >= dmap.RankMax {
+			t.Fatalf("coef %v: enemy's rank is %d, should be below RankMax", coef, d.Points[0][0])
+		}
+	}
+}
+
+func TestMoveEpsilon(t *testing.T) {
+	b := Behaviour{
+		Neighbours: dmap.ManhattanNeighbours,
+		Components: []Component{
+			{Goal: Enemies, Weight: 0.1},
+			{Goal: Items, Weight: 0.2},
+		},
+	}
+	mi := NewMind(b, openMap(2, 1))
+	// Both tiles are worth 1.2, but 0.1*0 + 0.2*6 rounds to
+	// 1.2000000000000002 while 0.1*2 + 0.2*5 rounds to 1.2.
+	mi.Maps[0].Points[0][0], mi.Maps[1].Points[0][0] = 0, 6
+	mi.Maps[0].Points[1][0], mi.Maps[1].Points[1][0] = 2, 5
+	if x, _ := mi.Move(0, 0); x != 1 {
+		t.Fatal("with no Epsilon, rounding error should decide the move")
+	}
+	mi.Behaviour.Epsilon = DefaultEpsilon
+	if x, _ := mi.Move(0, 0); x != 0 {
+		t.Fatal("scores within Epsilon of each other should tie and keep the creature still")
+	}
+	mi.Maps[1].Points[1][0] = 4
+	if x, _ := mi.Move(0, 0); x != 1 {
+		t.Fatal("a tile that's really better should still win")
+	}
+}
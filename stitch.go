@@ -0,0 +1,96 @@
+package dmap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// StitchedMap is a Map made out of a grid of smaller, adjacent Maps
+// (zones), such as the loaded zones around the player in a seamless
+// world. It translates coordinates on the fly rather than copying
+// tiles, so a single dmap can span zone boundaries.
+type StitchedMap struct {
+	zones [][]Map
+	xoff  []int
+	yoff  []int
+}
+
+// Stitch creates a StitchedMap from zones, where zones[i][j] is the
+// zone in column i and row j (matching the x, y order of
+// DijkstraMap.Points). Every zone in a column must have the same
+// SizeX, and every zone in a row must have the same SizeY. The grid is
+// copied, so changing zones afterwards won't affect the StitchedMap;
+// when zones are streamed in or out, stitch them again.
+func Stitch(zones [][]Map) (*StitchedMap, error) {
+	if len(zones) == 0 || len(zones[0]) == 0 {
+		return nil, errors.New("dmap: no zones to stitch")
+	}
+	rows := len(zones[0])
+	grid := make([][]Map, len(zones))
+	xoff := make([]int, len(zones)+1)
+	yoff := make([]int, rows+1)
+	for i := range zones {
+		if len(zones[i]) != rows {
+			return nil, fmt.Errorf("dmap: column %d has %d zones, want %d", i, len(zones[i]), rows)
+		}
+		for j := range zones[i] {
+			if zones[i][j] == nil {
+				return nil, fmt.Errorf("dmap: zone %d, %d is nil", i, j)
+			}
+			if sx := zones[i][j].SizeX(); sx != zones[i][0].SizeX() {
+				return nil, fmt.Errorf("dmap: zone %d, %d has SizeX %d, want %d", i, j, sx, zones[i][0].SizeX())
+			}
+			if sy := zones[i][j].SizeY(); sy != zones[0][j].SizeY() {
+				return nil, fmt.Errorf("dmap: zone %d, %d has SizeY %d, want %d", i, j, sy, zones[0][j].SizeY())
+			}
+		}
+		grid[i] = append([]Map(nil), zones[i]...)
+		xoff[i+1] = xoff[i] + zones[i][0].SizeX()
+	}
+	for j := range zones[0] {
+		yoff[j+1] = yoff[j] + zones[0][j].SizeY()
+	}
+	return &StitchedMap{grid, xoff, yoff}, nil
+}
+
+// SizeX implements the Map interface
+func (s *StitchedMap) SizeX() int {
+	return s.xoff[len(s.xoff)-1]
+}
+
+// SizeY implements the Map interface
+func (s *StitchedMap) SizeY() int {
+	return s.yoff[len(s.yoff)-1]
+}
+
+// OOB implements the Map interface
+func (s *StitchedMap) OOB(x, y int) bool {
+	return x < 0 || y < 0 || x >= s.SizeX() || y >= s.SizeY()
+}
+
+// IsPassable implements the Map interface by asking the zone that x, y
+// falls in.
+func (s *StitchedMap) IsPassable(x, y int) bool {
+	zone, lx, ly := s.Zone(x, y)
+	return zone != nil && zone.IsPassable(lx, ly)
+}
+
+// Zone returns the zone that the point x, y of the stitched map falls
+// in, along with the point's coordinates local to that zone. If x, y is
+// out of bounds, the zone is nil.
+func (s *StitchedMap) Zone(x, y int) (Map, int, int) {
+	if s.OOB(x, y) {
+		return nil, x, y
+	}
+	i := sort.SearchInts(s.xoff, x+1) - 1
+	j := sort.SearchInts(s.yoff, y+1) - 1
+	return s.zones[i][j], x - s.xoff[i], y - s.yoff[j]
+}
+
+// Global translates the point lx, ly local to the zone in column i and
+// row j into coordinates on the stitched map. i and j must be valid
+// indices into the grid passed to Stitch, or Global will panic.
+func (s *StitchedMap) Global(i, j, lx, ly int) (int, int) {
+	return s.xoff[i] + lx, s.yoff[j] + ly
+}
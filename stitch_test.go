@@ -0,0 +1,137 @@
+package dmap
+
+import "testing"
+
+func openMap(sx, sy int) *testMap {
+	walls := make([][]bool, sx)
+	for x := range walls {
+		walls[x] = make([]bool, sy)
+	}
+	return &testMap{walls}
+}
+
+// emptyMap is a zone with no tiles in it, which testMap can't represent
+type emptyMap struct {
+	sx, sy int
+}
+
+func (m emptyMap) SizeX() int {
+	return m.sx
+}
+
+func (m emptyMap) SizeY() int {
+	return m.sy
+}
+
+func (m emptyMap) IsPassable(x, y int) bool {
+	return false
+}
+
+func (m emptyMap) OOB(x, y int) bool {
+	return true
+}
+
+func TestStitchZoneGlobal(t *testing.T) {
+	widths := []int{2, 0, 3}
+	heights := []int{4, 1}
+	zones := make([][]Map, len(widths))
+	for i, w := range widths {
+		for _, h := range heights {
+			if w == 0 {
+				zones[i] = append(zones[i], emptyMap{0, h})
+			} else {
+				zones[i] = append(zones[i], openMap(w, h))
+			}
+		}
+	}
+	s, err := Stitch(zones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SizeX() != 5 || s.SizeY() != 5 {
+		t.Fatalf("size is %d, %d, want 5, 5", s.SizeX(), s.SizeY())
+	}
+	gx := 0
+	for i, w := range widths {
+		gy := 0
+		for j, h := range heights {
+			// Check both corners of every zone, which covers each side of
+			// every seam.
+			for _, l := range [][2]int{{0, 0}, {w - 1, h - 1}} {
+				if w == 0 {
+					continue
+				}
+				x, y := s.Global(i, j, l[0], l[1])
+				if x != gx+l[0] || y != gy+l[1] {
+					t.Fatalf("Global(%d, %d, %d, %d) = %d, %d, want %d, %d",
+						i, j, l[0], l[1], x, y, gx+l[0], gy+l[1])
+				}
+				zone, lx, ly := s.Zone(x, y)
+				if zone != zones[i][j] || lx != l[0] || ly != l[1] {
+					t.Fatalf("Zone(%d, %d) = zone %v, %d, %d, want zone %d, %d, %d, %d",
+						x, y, zone, lx, ly, i, j, l[0], l[1])
+				}
+			}
+			gy += h
+		}
+		gx += w
+	}
+	for _, p := range [][2]int{{-1, 0}, {0, -1}, {5, 0}, {0, 5}} {
+		if zone, _, _ := s.Zone(p[0], p[1]); zone != nil || !s.OOB(p[0], p[1]) {
+			t.Fatalf("Zone(%d, %d) should be out of bounds", p[0], p[1])
+		}
+	}
+}
+
+func TestStitchIsPassable(t *testing.T) {
+	wall := openMap(3, 2)
+	wall.walls[1][0] = true
+	s, err := Stitch([][]Map{
+		{openMap(2, 2), openMap(2, 2)},
+		{openMap(3, 2), wall},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.IsPassable(3, 2) {
+		t.Fatal("wall at 1, 0 of zone 1, 1 should make 3, 2 impassable")
+	}
+	if !s.IsPassable(2, 2) || !s.IsPassable(3, 1) {
+		t.Fatal("tiles next to the wall should be passable")
+	}
+}
+
+func TestStitchCalcAcrossSeam(t *testing.T) {
+	left := openMap(3, 3)
+	left.walls[2][0] = true
+	left.walls[2][1] = true
+	s, err := Stitch([][]Map{{left}, {openMap(3, 3)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := BlankDMap(s, ManhattanNeighbours)
+	d.Calc(&WeightedPoint{X: 0, Y: 0})
+	// The only way through the wall is its gap at 2, 2, which is 4 steps
+	// from the target and 5 from the top-right corner.
+	if d.Points[5][0] != 9 {
+		t.Fatalf("rank across the seam is %d, want 9\n%s", d.Points[5][0], d)
+	}
+}
+
+func TestStitchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		zones [][]Map
+	}{
+		{"empty", nil},
+		{"ragged", [][]Map{{openMap(1, 1), openMap(1, 1)}, {openMap(1, 1)}}},
+		{"nil zone", [][]Map{{openMap(1, 1), nil}}},
+		{"column width", [][]Map{{openMap(1, 1), openMap(2, 1)}}},
+		{"row height", [][]Map{{openMap(1, 1)}, {openMap(1, 2)}}},
+	}
+	for _, test := range tests {
+		if s, err := Stitch(test.zones); err == nil || s != nil {
+			t.Errorf("%s: Stitch should have failed", test.name)
+		}
+	}
+}